@@ -0,0 +1,212 @@
+// Package backend declares the interface speedtest_exporter uses to run
+// individual test phases, so the collector in internal/exporter does not
+// need to know whether a given scrape is actually talking to the
+// showwin/speedtest-go library, shelling out to the Ookla CLI, or driving
+// iperf3.
+package backend
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+)
+
+// UserInfo is the caller's own network identity, as reported by whichever
+// backend is in use.
+type UserInfo struct {
+	IP  string `json:"ip"`
+	ISP string `json:"isp"`
+	Lat string `json:"lat"`
+	Lon string `json:"lon"`
+}
+
+// ServerInfo identifies a candidate test server. Handle carries whatever
+// backend-specific state (e.g. a *speedtest.Server) the backend needs to
+// hand between SelectServer and the Ping/Download/Upload calls that follow
+// it; only the backend that produced a ServerInfo should read its Handle.
+type ServerInfo struct {
+	ID      string      `json:"id"`
+	Name    string      `json:"name"`
+	Country string      `json:"country"`
+	Host    string      `json:"host"`
+	Lat     string      `json:"lat"`
+	Lon     string      `json:"lon"`
+	Handle  interface{} `json:"-"`
+}
+
+// PingStats summarizes a round of latency samples against a server.
+type PingStats struct {
+	MedianSeconds   float64
+	MinSeconds      float64
+	MaxSeconds      float64
+	JitterSeconds   float64
+	PacketLossRatio float64
+}
+
+// Backend runs the individual phases of a speedtest. Implementations are
+// free to run all phases eagerly in SelectServer and simply report cached
+// results from Ping/Download/Upload, if that is how the underlying tool
+// works (e.g. the Ookla CLI).
+type Backend interface {
+	// Name identifies the backend, e.g. for logging or a backend label.
+	Name() string
+	// FetchUser returns the caller's own network identity.
+	FetchUser() (*UserInfo, error)
+	// SelectServer narrows the backend's server list to serverIDs and/or
+	// countryFilter (either may be empty/nil to mean "no filter"), then
+	// picks one using selection. It returns the chosen server and its
+	// distance from user in kilometers.
+	//
+	// skipDownload and skipUpload describe a latency-only probe. Backends
+	// that measure phases lazily (Download/Upload only run when called)
+	// may ignore them; a backend whose underlying tool runs every phase
+	// together as part of server selection (e.g. the Ookla CLI) must
+	// honor them here, since Download/Upload will never be called to skip.
+	SelectServer(user *UserInfo, serverIDs []int, countryFilter string, selection Selection, skipDownload, skipUpload bool) (*ServerInfo, float64, error)
+	// Ping runs samples latency probes against server.
+	Ping(server *ServerInfo, samples int) (PingStats, error)
+	// Download measures download throughput against server, in bytes/sec.
+	Download(server *ServerInfo) (float64, error)
+	// Upload measures upload throughput against server, in bytes/sec.
+	Upload(server *ServerInfo) (float64, error)
+}
+
+// Selection controls how a Backend picks a server out of its candidate pool.
+type Selection int
+
+const (
+	// SelectionNearest picks the candidate with the smallest haversine
+	// distance to the user, computed locally rather than trusting the
+	// order a server list happened to be returned in.
+	SelectionNearest Selection = iota
+	// SelectionRoundRobin cycles through the candidate pool in order, one
+	// server per call to SelectServer.
+	SelectionRoundRobin
+	// SelectionRandom picks a uniformly random candidate on each call.
+	SelectionRandom
+)
+
+// String renders a Selection the way it appears in the
+// selected_server_info metric's selection_strategy label.
+func (s Selection) String() string {
+	switch s {
+	case SelectionRoundRobin:
+		return "round_robin"
+	case SelectionRandom:
+		return "random"
+	default:
+		return "nearest"
+	}
+}
+
+// earthRadiusKm is used to convert the haversine angular distance between
+// two points into kilometers.
+const earthRadiusKm = 6371.0
+
+// HaversineKm returns the great-circle distance in kilometers between two
+// points given in decimal degrees.
+func HaversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	deltaPhi := (lat2 - lat1) * math.Pi / 180
+	deltaLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(deltaPhi/2)*math.Sin(deltaPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(deltaLambda/2)*math.Sin(deltaLambda/2)
+	c := 2 * math.Asin(math.Sqrt(a))
+
+	return earthRadiusKm * c
+}
+
+// RoundRobinCounter hands out sequential indices into a candidate pool. Each
+// backend that supports SelectionRoundRobin should keep one of these.
+type RoundRobinCounter struct {
+	mu    sync.Mutex
+	index int
+}
+
+// Next returns the next index in [0, n) and advances the counter.
+func (c *RoundRobinCounter) Next(n int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.index % n
+	c.index++
+	return i
+}
+
+// SelectCandidate picks one of candidates according to selection, returning
+// the chosen server and its haversine distance from user in kilometers. rr
+// is only consulted (and may be nil) when selection is SelectionRoundRobin.
+func SelectCandidate(user *UserInfo, candidates []*ServerInfo, selection Selection, rr *RoundRobinCounter) (*ServerInfo, float64, error) {
+	if len(candidates) == 0 {
+		return nil, 0, fmt.Errorf("no candidate servers available to select from")
+	}
+
+	userLat, userLon, err := ParseLatLon(user.Lat, user.Lon)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not parse user coordinates: %w", err)
+	}
+
+	switch selection {
+	case SelectionRoundRobin:
+		idx := 0
+		if rr != nil {
+			idx = rr.Next(len(candidates))
+		}
+		server := candidates[idx]
+		d, err := distanceTo(userLat, userLon, server)
+		return server, d, err
+	case SelectionRandom:
+		server := candidates[rand.Intn(len(candidates))]
+		d, err := distanceTo(userLat, userLon, server)
+		return server, d, err
+	default: // SelectionNearest
+		return nearest(userLat, userLon, candidates)
+	}
+}
+
+func nearest(userLat, userLon float64, candidates []*ServerInfo) (*ServerInfo, float64, error) {
+	var best *ServerInfo
+	bestDistanceKm := math.Inf(1)
+
+	for _, s := range candidates {
+		lat, lon, err := ParseLatLon(s.Lat, s.Lon)
+		if err != nil {
+			continue
+		}
+		d := HaversineKm(userLat, userLon, lat, lon)
+		if d < bestDistanceKm {
+			bestDistanceKm = d
+			best = s
+		}
+	}
+
+	if best == nil {
+		return nil, 0, fmt.Errorf("could not compute distance to any candidate server")
+	}
+	return best, bestDistanceKm, nil
+}
+
+func distanceTo(userLat, userLon float64, server *ServerInfo) (float64, error) {
+	lat, lon, err := ParseLatLon(server.Lat, server.Lon)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse coordinates for server %s: %w", server.ID, err)
+	}
+	return HaversineKm(userLat, userLon, lat, lon), nil
+}
+
+// ParseLatLon is a small shared helper for backends that represent
+// coordinates as decimal-degree strings, as speedtest.net and its API do.
+func ParseLatLon(latStr, lonStr string) (float64, float64, error) {
+	lat, err := strconv.ParseFloat(latStr, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid latitude %q: %w", latStr, err)
+	}
+	lon, err := strconv.ParseFloat(lonStr, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid longitude %q: %w", lonStr, err)
+	}
+	return lat, lon, nil
+}
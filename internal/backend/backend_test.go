@@ -0,0 +1,122 @@
+package backend
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversineKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		wantKm                 float64
+		tolerance              float64
+	}{
+		{
+			name: "same point is zero distance",
+			lat1: 51.5074, lon1: -0.1278,
+			lat2: 51.5074, lon2: -0.1278,
+			wantKm: 0, tolerance: 0.001,
+		},
+		{
+			name: "london to paris",
+			lat1: 51.5074, lon1: -0.1278,
+			lat2: 48.8566, lon2: 2.3522,
+			wantKm: 343.5, tolerance: 1,
+		},
+		{
+			name: "antipodal points span half the earth's circumference",
+			lat1: 0, lon1: 0,
+			lat2: 0, lon2: 180,
+			wantKm: math.Pi * earthRadiusKm, tolerance: 0.001,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HaversineKm(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if math.Abs(got-tt.wantKm) > tt.tolerance {
+				t.Errorf("HaversineKm(%v, %v, %v, %v) = %v, want %v (+/- %v)",
+					tt.lat1, tt.lon1, tt.lat2, tt.lon2, got, tt.wantKm, tt.tolerance)
+			}
+		})
+	}
+}
+
+func TestParseLatLon(t *testing.T) {
+	if _, _, err := ParseLatLon("not-a-number", "0"); err == nil {
+		t.Error("expected an error for a non-numeric latitude, got nil")
+	}
+	if _, _, err := ParseLatLon("0", "not-a-number"); err == nil {
+		t.Error("expected an error for a non-numeric longitude, got nil")
+	}
+
+	lat, lon, err := ParseLatLon("51.5074", "-0.1278")
+	if err != nil {
+		t.Fatalf("ParseLatLon returned an unexpected error: %s", err)
+	}
+	if lat != 51.5074 || lon != -0.1278 {
+		t.Errorf("ParseLatLon() = (%v, %v), want (51.5074, -0.1278)", lat, lon)
+	}
+}
+
+func TestSelectCandidateNearest(t *testing.T) {
+	user := &UserInfo{Lat: "0", Lon: "0"}
+	candidates := []*ServerInfo{
+		{ID: "far", Lat: "10", Lon: "10"},
+		{ID: "near", Lat: "1", Lon: "1"},
+		{ID: "unparseable", Lat: "nope", Lon: "0"},
+	}
+
+	got, distanceKm, err := SelectCandidate(user, candidates, SelectionNearest, nil)
+	if err != nil {
+		t.Fatalf("SelectCandidate returned an unexpected error: %s", err)
+	}
+	if got.ID != "near" {
+		t.Errorf("SelectCandidate picked %q, want %q", got.ID, "near")
+	}
+	if distanceKm <= 0 {
+		t.Errorf("SelectCandidate reported non-positive distance %v for a non-identical point", distanceKm)
+	}
+}
+
+func TestSelectCandidateRoundRobin(t *testing.T) {
+	user := &UserInfo{Lat: "0", Lon: "0"}
+	candidates := []*ServerInfo{
+		{ID: "a", Lat: "1", Lon: "1"},
+		{ID: "b", Lat: "2", Lon: "2"},
+		{ID: "c", Lat: "3", Lon: "3"},
+	}
+
+	var rr RoundRobinCounter
+	var got []string
+	for i := 0; i < len(candidates)*2; i++ {
+		server, _, err := SelectCandidate(user, candidates, SelectionRoundRobin, &rr)
+		if err != nil {
+			t.Fatalf("SelectCandidate returned an unexpected error: %s", err)
+		}
+		got = append(got, server.ID)
+	}
+
+	want := []string{"a", "b", "c", "a", "b", "c"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("SelectCandidate sequence = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSelectCandidateNoCandidates(t *testing.T) {
+	user := &UserInfo{Lat: "0", Lon: "0"}
+	if _, _, err := SelectCandidate(user, nil, SelectionNearest, nil); err == nil {
+		t.Error("expected an error when no candidates are given, got nil")
+	}
+}
+
+func TestSelectCandidateInvalidUserCoordinates(t *testing.T) {
+	user := &UserInfo{Lat: "nope", Lon: "0"}
+	candidates := []*ServerInfo{{ID: "a", Lat: "1", Lon: "1"}}
+	if _, _, err := SelectCandidate(user, candidates, SelectionNearest, nil); err == nil {
+		t.Error("expected an error for unparseable user coordinates, got nil")
+	}
+}
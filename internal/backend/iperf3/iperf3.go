@@ -0,0 +1,176 @@
+// Package iperf3 implements backend.Backend by shelling out to the iperf3
+// client against a user-supplied iperf3 server, for LAN/WAN throughput
+// testing without relying on speedtest.net at all.
+//
+// iperf3 has no concept of "select the nearest server" or a public
+// user/server registry, so FetchUser and SelectServer are mostly
+// bookkeeping around the single configured target.
+package iperf3
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os/exec"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/C0mmanderCrud/speedtest_exporter/internal/backend"
+)
+
+// binary is the name of the iperf3 client executable, resolved via $PATH.
+const binary = "iperf3"
+
+// Backend is a backend.Backend that drives the iperf3 client against a
+// single, pre-configured iperf3 server.
+type Backend struct {
+	host string
+	port int
+}
+
+// New returns an iperf3-backed Backend targeting host:port.
+func New(host string, port int) *Backend {
+	return &Backend{host: host, port: port}
+}
+
+// Name implements backend.Backend.
+func (b *Backend) Name() string { return "iperf3" }
+
+// FetchUser implements backend.Backend. iperf3 has no notion of the
+// caller's public IP/ISP/location, so this returns an otherwise-empty
+// UserInfo; it exists purely so the collector's calling convention stays
+// identical across backends.
+func (b *Backend) FetchUser() (*backend.UserInfo, error) {
+	return &backend.UserInfo{}, nil
+}
+
+// SelectServer implements backend.Backend. serverIDs, countryFilter and
+// selection are all ignored: the iperf3 target is fixed at construction
+// time, there being no server list to choose from. skipDownload and
+// skipUpload are likewise ignored: Download/Upload each run their own
+// iperf3 invocation, so a caller that skips calling them already gets a
+// latency-only probe for free.
+func (b *Backend) SelectServer(user *backend.UserInfo, serverIDs []int, countryFilter string, selection backend.Selection, skipDownload, skipUpload bool) (*backend.ServerInfo, float64, error) {
+	return &backend.ServerInfo{
+		ID:   fmt.Sprintf("%s:%d", b.host, b.port),
+		Name: b.host,
+		Host: fmt.Sprintf("%s:%d", b.host, b.port),
+	}, 0, nil
+}
+
+// summary mirrors the subset of `iperf3 --json` this backend cares about.
+type summary struct {
+	End struct {
+		SumReceived struct {
+			BitsPerSecond float64 `json:"bits_per_second"`
+		} `json:"sum_received"`
+		SumSent struct {
+			BitsPerSecond float64 `json:"bits_per_second"`
+		} `json:"sum_sent"`
+	} `json:"end"`
+}
+
+// Ping implements backend.Backend. iperf3 has no ping phase, so this
+// approximates latency with samples bare TCP connect round-trips against
+// the configured server; a failed connect attempt is treated as a lost
+// sample and counts towards the reported packet loss ratio.
+func (b *Backend) Ping(s *backend.ServerInfo, samples int) (backend.PingStats, error) {
+	rtts := make([]float64, 0, samples)
+	lost := 0
+
+	for i := 0; i < samples; i++ {
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", s.Host, 5*time.Second)
+		if err != nil {
+			lost++
+			continue
+		}
+		rtts = append(rtts, time.Since(start).Seconds())
+		conn.Close()
+	}
+
+	if len(rtts) == 0 {
+		return backend.PingStats{}, fmt.Errorf("all %d connect attempts to %s failed", samples, s.Host)
+	}
+
+	return pingStatsFromRTTs(rtts, lost, samples), nil
+}
+
+// pingStatsFromRTTs aggregates connect round-trip times, measured in
+// chronological order, into PingStats. Jitter is the mean absolute
+// difference between consecutive samples, so it is computed over rtts as
+// measured; min/max are tracked in the same pass, and the median is taken
+// from a sorted copy so that it doesn't depend on which sample happened to
+// land in the middle chronologically.
+func pingStatsFromRTTs(rtts []float64, lost, samples int) backend.PingStats {
+	var jitterSum, min, max float64
+	min, max = rtts[0], rtts[0]
+	for i, rtt := range rtts {
+		if rtt < min {
+			min = rtt
+		}
+		if rtt > max {
+			max = rtt
+		}
+		if i > 0 {
+			diff := rtt - rtts[i-1]
+			if diff < 0 {
+				diff = -diff
+			}
+			jitterSum += diff
+		}
+	}
+	var jitterSeconds float64
+	if len(rtts) > 1 {
+		jitterSeconds = jitterSum / float64(len(rtts)-1)
+	}
+
+	sorted := append([]float64(nil), rtts...)
+	sort.Float64s(sorted)
+
+	return backend.PingStats{
+		MedianSeconds:   sorted[len(sorted)/2],
+		MinSeconds:      min,
+		MaxSeconds:      max,
+		JitterSeconds:   jitterSeconds,
+		PacketLossRatio: float64(lost) / float64(samples),
+	}
+}
+
+// Download implements backend.Backend by running iperf3 in reverse mode (-R),
+// so the server sends and this host receives.
+func (b *Backend) Download(s *backend.ServerInfo) (float64, error) {
+	return b.run(s, "-R")
+}
+
+// Upload implements backend.Backend by running iperf3 in its default
+// direction, where this host sends and the server receives.
+func (b *Backend) Upload(s *backend.ServerInfo) (float64, error) {
+	return b.run(s)
+}
+
+func (b *Backend) run(s *backend.ServerInfo, extraArgs ...string) (float64, error) {
+	args := append([]string{"-c", b.host, "-p", strconv.Itoa(b.port), "--json"}, extraArgs...)
+	cmd := exec.Command(binary, args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return 0, fmt.Errorf("%s %v: %w (stderr: %s)", binary, args, err, stderr.String())
+	}
+
+	var sum summary
+	if err := json.Unmarshal(stdout.Bytes(), &sum); err != nil {
+		return 0, fmt.Errorf("could not parse %s output: %w", binary, err)
+	}
+
+	bitsPerSecond := sum.End.SumReceived.BitsPerSecond
+	if bitsPerSecond == 0 {
+		bitsPerSecond = sum.End.SumSent.BitsPerSecond
+	}
+	return bitsPerSecond / 8, nil
+}
@@ -0,0 +1,81 @@
+package iperf3
+
+import (
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/C0mmanderCrud/speedtest_exporter/internal/backend"
+)
+
+// listen starts a listener that accepts and immediately closes every
+// connection, standing in for a reachable iperf3 server's control port.
+func listen(t *testing.T) (host string, port int) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not start test listener: %s", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestPingAllSamplesSucceed(t *testing.T) {
+	host, port := listen(t)
+	b := New(host, port)
+	server := &backend.ServerInfo{Host: net.JoinHostPort(host, strconv.Itoa(port))}
+
+	stats, err := b.Ping(server, 5)
+	if err != nil {
+		t.Fatalf("Ping returned an unexpected error: %s", err)
+	}
+	if stats.PacketLossRatio != 0 {
+		t.Errorf("PacketLossRatio = %v, want 0 for an all-reachable server", stats.PacketLossRatio)
+	}
+	if stats.MedianSeconds < 0 || stats.MinSeconds < 0 || stats.MaxSeconds < 0 {
+		t.Errorf("negative RTT in stats: %+v", stats)
+	}
+	if stats.MinSeconds > stats.MedianSeconds || stats.MedianSeconds > stats.MaxSeconds {
+		t.Errorf("expected MinSeconds <= MedianSeconds <= MaxSeconds, got %+v", stats)
+	}
+}
+
+func TestPingStatsFromRTTsMedianIsSorted(t *testing.T) {
+	// Chronological order is high, low, middle: the middle element by sample
+	// order (0.1) is not the sorted median (0.2).
+	rtts := []float64{0.3, 0.1, 0.2}
+
+	stats := pingStatsFromRTTs(rtts, 0, len(rtts))
+
+	if stats.MedianSeconds != 0.2 {
+		t.Errorf("MedianSeconds = %v, want 0.2 (the sorted median), not the sample-order middle element", stats.MedianSeconds)
+	}
+	if stats.MinSeconds != 0.1 {
+		t.Errorf("MinSeconds = %v, want 0.1", stats.MinSeconds)
+	}
+	if stats.MaxSeconds != 0.3 {
+		t.Errorf("MaxSeconds = %v, want 0.3", stats.MaxSeconds)
+	}
+}
+
+func TestPingAllSamplesLost(t *testing.T) {
+	// Nothing listens on this port, so every connect attempt fails.
+	server := &backend.ServerInfo{Host: "127.0.0.1:1"}
+	b := New("127.0.0.1", 1)
+
+	if _, err := b.Ping(server, 3); err == nil {
+		t.Error("expected an error when every connect attempt fails, got nil")
+	}
+}
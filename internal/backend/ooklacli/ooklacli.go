@@ -0,0 +1,227 @@
+// Package ooklacli implements backend.Backend by shelling out to the
+// official Ookla "speedtest" CLI and parsing its --format=json output.
+//
+// The CLI always runs ping, download and upload together in a single
+// invocation and reports bandwidth natively in bytes/sec, so unlike the
+// speedtestgo backend there is no Mbps-vs-Bps heuristic to apply. Because
+// of that one-shot design, SelectServer runs the full test (honoring
+// skipDownload/skipUpload as --no-download/--no-upload CLI flags, since
+// there is no later Download/Upload call to skip) and caches the decoded
+// result on the returned backend.ServerInfo and on Backend itself;
+// Ping/Download/Upload just read back the phase they were asked for.
+package ooklacli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os/exec"
+	"strconv"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/C0mmanderCrud/speedtest_exporter/internal/backend"
+)
+
+// binary is the name of the Ookla CLI executable, resolved via $PATH.
+const binary = "speedtest"
+
+// Backend is a backend.Backend that drives the Ookla CLI.
+type Backend struct {
+	rr backend.RoundRobinCounter
+
+	mu   sync.Mutex
+	last *result
+}
+
+// New returns an Ookla CLI-backed Backend.
+func New() *Backend {
+	return &Backend{}
+}
+
+// Name implements backend.Backend.
+func (b *Backend) Name() string { return "ookla-cli" }
+
+// result mirrors the subset of `speedtest --format=json` that this backend
+// cares about.
+type result struct {
+	Ping struct {
+		Jitter  float64 `json:"jitter"`
+		Latency float64 `json:"latency"`
+	} `json:"ping"`
+	Download struct {
+		Bandwidth float64 `json:"bandwidth"`
+	} `json:"download"`
+	Upload struct {
+		Bandwidth float64 `json:"bandwidth"`
+	} `json:"upload"`
+	PacketLoss float64 `json:"packetLoss"`
+	ISP        string  `json:"isp"`
+	Interface  struct {
+		InternalIP string `json:"internalIp"`
+		ExternalIP string `json:"externalIp"`
+	} `json:"interface"`
+	Server struct {
+		ID       int    `json:"id"`
+		Host     string `json:"host"`
+		Name     string `json:"name"`
+		Location string `json:"location"`
+		Country  string `json:"country"`
+	} `json:"server"`
+}
+
+// FetchUser implements backend.Backend. The Ookla CLI does not expose a
+// ping/download/upload-less "just tell me who I am" mode, and SelectServer
+// already runs a full test every scrape, so this reads isp/interface off
+// the previous SelectServer result instead of paying for a second live
+// test. Only the very first call of the process, before any SelectServer
+// call has completed, falls back to a real ping-only pass.
+func (b *Backend) FetchUser() (*backend.UserInfo, error) {
+	b.mu.Lock()
+	r := b.last
+	b.mu.Unlock()
+
+	if r == nil {
+		var err error
+		r, err = runSpeedtest("--no-download", "--no-upload")
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch user information: %w", err)
+		}
+	}
+	return &backend.UserInfo{IP: r.Interface.ExternalIP, ISP: r.ISP}, nil
+}
+
+// SelectServer implements backend.Backend. The Ookla CLI does not report
+// client or server coordinates, so SelectionNearest cannot be computed
+// locally here: with no serverIDs configured the CLI picks its own nearest
+// server, and with serverIDs configured the first of them is used. Distance
+// is always reported as 0.
+//
+// Because the CLI runs every phase in one invocation, skipDownload and
+// skipUpload are passed through as --no-download/--no-upload here rather
+// than being left for Download/Upload to honor.
+func (b *Backend) SelectServer(user *backend.UserInfo, serverIDs []int, countryFilter string, selection backend.Selection, skipDownload, skipUpload bool) (*backend.ServerInfo, float64, error) {
+	if countryFilter != "" {
+		log.Warnf("ooklacli backend does not support country filtering, ignoring country filter %q", countryFilter)
+	}
+
+	var args []string
+	if id, ok := b.pickServerID(serverIDs, selection); ok {
+		args = append(args, "--server-id", strconv.Itoa(id))
+	}
+	if skipDownload {
+		args = append(args, "--no-download")
+	}
+	if skipUpload {
+		args = append(args, "--no-upload")
+	}
+
+	r, err := runSpeedtest(args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("speedtest run failed: %w", err)
+	}
+
+	b.mu.Lock()
+	b.last = r
+	b.mu.Unlock()
+
+	info := &backend.ServerInfo{
+		ID:      strconv.Itoa(r.Server.ID),
+		Name:    r.Server.Name,
+		Country: r.Server.Country,
+		Host:    r.Server.Host,
+		Handle:  r,
+	}
+	return info, 0, nil
+}
+
+// pickServerID picks a server ID out of serverIDs according to selection.
+// When serverIDs is empty it reports ok=false so the CLI is left to pick
+// its own nearest server; for SelectionRoundRobin and SelectionRandom that
+// fallback is logged, since there is nothing configured to rotate or pick
+// randomly among, so it isn't silently mistaken for the requested strategy
+// having run. When serverIDs is non-empty, SelectionNearest returns the
+// first configured ID, there being no coordinates to rank them by.
+func (b *Backend) pickServerID(serverIDs []int, selection backend.Selection) (int, bool) {
+	if len(serverIDs) == 0 {
+		if selection == backend.SelectionRoundRobin || selection == backend.SelectionRandom {
+			log.Warnf("ooklacli backend has no configured server IDs to select %s among, falling back to the CLI's own nearest-server pick", selection)
+		}
+		return 0, false
+	}
+	switch selection {
+	case backend.SelectionRoundRobin:
+		return serverIDs[b.rr.Next(len(serverIDs))], true
+	case backend.SelectionRandom:
+		return serverIDs[rand.Intn(len(serverIDs))], true
+	default: // SelectionNearest: no local coordinates to rank serverIDs by
+		return serverIDs[0], true
+	}
+}
+
+// Ping implements backend.Backend, reporting the ping phase already
+// captured by SelectServer. samples is ignored: the Ookla CLI does not
+// expose per-sample RTTs, only an aggregate latency and jitter.
+func (b *Backend) Ping(s *backend.ServerInfo, samples int) (backend.PingStats, error) {
+	r, err := cached(s)
+	if err != nil {
+		return backend.PingStats{}, err
+	}
+	latencySeconds := r.Ping.Latency / 1000
+	return backend.PingStats{
+		MedianSeconds:   latencySeconds,
+		MinSeconds:      latencySeconds,
+		MaxSeconds:      latencySeconds,
+		JitterSeconds:   r.Ping.Jitter / 1000,
+		PacketLossRatio: r.PacketLoss / 100,
+	}, nil
+}
+
+// Download implements backend.Backend, reporting the download phase
+// already captured by SelectServer.
+func (b *Backend) Download(s *backend.ServerInfo) (float64, error) {
+	r, err := cached(s)
+	if err != nil {
+		return 0, err
+	}
+	return r.Download.Bandwidth, nil
+}
+
+// Upload implements backend.Backend, reporting the upload phase already
+// captured by SelectServer.
+func (b *Backend) Upload(s *backend.ServerInfo) (float64, error) {
+	r, err := cached(s)
+	if err != nil {
+		return 0, err
+	}
+	return r.Upload.Bandwidth, nil
+}
+
+func cached(s *backend.ServerInfo) (*result, error) {
+	r, ok := s.Handle.(*result)
+	if !ok || r == nil {
+		return nil, fmt.Errorf("server %s was not selected by this backend", s.ID)
+	}
+	return r, nil
+}
+
+func runSpeedtest(extraArgs ...string) (*result, error) {
+	args := append([]string{"--format=json", "--accept-license", "--accept-gdpr"}, extraArgs...)
+	cmd := exec.Command(binary, args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s %v: %w (stderr: %s)", binary, args, err, stderr.String())
+	}
+
+	var r result
+	if err := json.Unmarshal(stdout.Bytes(), &r); err != nil {
+		return nil, fmt.Errorf("could not parse %s output: %w", binary, err)
+	}
+	return &r, nil
+}
@@ -0,0 +1,212 @@
+// Package speedtestgo implements backend.Backend on top of the
+// showwin/speedtest-go library, talking to speedtest.net the same way the
+// exporter always has.
+package speedtestgo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/showwin/speedtest-go/speedtest"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/C0mmanderCrud/speedtest_exporter/internal/backend"
+)
+
+// speedThreshold is the sanity threshold for speed values. If a value is >
+// 20,000, we assume it's an anomaly reported in B/s, not Mbps.
+const speedThreshold = 20000.0
+
+// Backend is a backend.Backend that drives showwin/speedtest-go.
+type Backend struct {
+	serverFallback bool
+	rr             backend.RoundRobinCounter
+}
+
+// New returns a speedtest-go-backed Backend. serverFallback controls whether
+// SelectServer falls back to the unfiltered server list when serverIDs or
+// countryFilter match nothing.
+func New(serverFallback bool) *Backend {
+	return &Backend{serverFallback: serverFallback}
+}
+
+// Name implements backend.Backend.
+func (b *Backend) Name() string { return "speedtest-go" }
+
+// FetchUser implements backend.Backend.
+func (b *Backend) FetchUser() (*backend.UserInfo, error) {
+	user, err := speedtest.FetchUserInfo()
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch user information: %w", err)
+	}
+	return &backend.UserInfo{IP: user.IP, ISP: user.Isp, Lat: user.Lat, Lon: user.Lon}, nil
+}
+
+// SelectServer implements backend.Backend. skipDownload and skipUpload are
+// ignored: this backend measures phases lazily, so a caller that never
+// calls Download/Upload already gets a latency-only probe for free.
+func (b *Backend) SelectServer(user *backend.UserInfo, serverIDs []int, countryFilter string, selection backend.Selection, skipDownload, skipUpload bool) (*backend.ServerInfo, float64, error) {
+	speedtestUser := &speedtest.User{IP: user.IP, Isp: user.ISP, Lat: user.Lat, Lon: user.Lon}
+
+	serverList, err := speedtest.FetchServerList(speedtestUser)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not fetch server list: %w", err)
+	}
+
+	candidates := serverList.Servers
+
+	if countryFilter != "" {
+		var filtered []*speedtest.Server
+		for _, s := range candidates {
+			if s.Country == countryFilter {
+				filtered = append(filtered, s)
+			}
+		}
+		candidates = b.applyFallback(filtered, serverList.Servers, fmt.Sprintf("country filter %q matched no servers", countryFilter))
+	}
+
+	if len(serverIDs) > 0 {
+		found, err := serverList.FindServer(serverIDs)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to find servers with IDs %v: %w", serverIDs, err)
+		}
+		candidates = b.applyFallback(found, candidates, fmt.Sprintf("none of the configured server IDs %v were found", serverIDs))
+	}
+
+	infos := make([]*backend.ServerInfo, 0, len(candidates))
+	byID := make(map[string]*speedtest.Server, len(candidates))
+	for _, s := range candidates {
+		infos = append(infos, &backend.ServerInfo{
+			ID: s.ID, Name: s.Name, Country: s.Country, Host: s.Host, Lat: s.Lat, Lon: s.Lon,
+		})
+		byID[s.ID] = s
+	}
+
+	chosen, distanceKm, err := backend.SelectCandidate(user, infos, selection, &b.rr)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	server := byID[chosen.ID]
+
+	// WORKAROUND: Detect and correct malformed URLs (e.g., "http//...")
+	// that can be produced by the speedtest-go library or server lists.
+	if strings.HasPrefix(server.URL, "http//") {
+		correctedURL := strings.Replace(server.URL, "http//", "http://", 1)
+		log.Warnf("Malformed server URL detected, correcting from '%s' to '%s'", server.URL, correctedURL)
+		server.URL = correctedURL
+	}
+
+	chosen.Handle = server
+	return chosen, distanceKm, nil
+}
+
+// applyFallback returns filtered if it is non-empty. Otherwise, if
+// serverFallback is enabled it logs why and falls back to fallback; if not,
+// it returns filtered (empty) unchanged so the caller reports the error.
+func (b *Backend) applyFallback(filtered, fallback []*speedtest.Server, reason string) []*speedtest.Server {
+	if len(filtered) > 0 {
+		return filtered
+	}
+	if !b.serverFallback {
+		return filtered
+	}
+	log.Infof("%s, server_fallback is enabled, falling back to the wider candidate pool", reason)
+	return fallback
+}
+
+// server recovers the underlying *speedtest.Server stashed in s.Handle by
+// SelectServer.
+func server(s *backend.ServerInfo) (*speedtest.Server, error) {
+	srv, ok := s.Handle.(*speedtest.Server)
+	if !ok || srv == nil {
+		return nil, fmt.Errorf("server %s was not selected by this backend", s.ID)
+	}
+	return srv, nil
+}
+
+// Ping implements backend.Backend, sending samples sequential pings and
+// reporting min/median/max latency, jitter (the mean absolute difference
+// between consecutive samples) and the fraction of samples that timed out.
+func (b *Backend) Ping(s *backend.ServerInfo, samples int) (backend.PingStats, error) {
+	srv, err := server(s)
+	if err != nil {
+		return backend.PingStats{}, err
+	}
+
+	rtts := make([]float64, 0, samples)
+	lost := 0
+
+	for i := 0; i < samples; i++ {
+		if err := srv.PingTest(); err != nil {
+			log.Warnf("ping sample %d/%d timed out: %s", i+1, samples, err.Error())
+			lost++
+			continue
+		}
+		rtts = append(rtts, srv.Latency.Seconds())
+	}
+
+	if len(rtts) == 0 {
+		return backend.PingStats{}, fmt.Errorf("all %d ping samples were lost", samples)
+	}
+
+	var jitterSum float64
+	for i := 1; i < len(rtts); i++ {
+		diff := rtts[i] - rtts[i-1]
+		if diff < 0 {
+			diff = -diff
+		}
+		jitterSum += diff
+	}
+	var jitterSeconds float64
+	if len(rtts) > 1 {
+		jitterSeconds = jitterSum / float64(len(rtts)-1)
+	}
+
+	sorted := append([]float64(nil), rtts...)
+	sort.Float64s(sorted)
+
+	return backend.PingStats{
+		MinSeconds:      sorted[0],
+		MedianSeconds:   sorted[len(sorted)/2],
+		MaxSeconds:      sorted[len(sorted)-1],
+		JitterSeconds:   jitterSeconds,
+		PacketLossRatio: float64(lost) / float64(samples),
+	}, nil
+}
+
+// Download implements backend.Backend.
+func (b *Backend) Download(s *backend.ServerInfo) (float64, error) {
+	srv, err := server(s)
+	if err != nil {
+		return 0, err
+	}
+	if err := srv.DownloadTest(false); err != nil {
+		return 0, fmt.Errorf("failed to carry out download test: %w", err)
+	}
+	return toBps(srv.DLSpeed), nil
+}
+
+// Upload implements backend.Backend.
+func (b *Backend) Upload(s *backend.ServerInfo) (float64, error) {
+	srv, err := server(s)
+	if err != nil {
+		return 0, err
+	}
+	if err := srv.UploadTest(false); err != nil {
+		return 0, fmt.Errorf("failed to carry out upload test: %w", err)
+	}
+	return toBps(srv.ULSpeed), nil
+}
+
+// toBps applies the heuristic that handles inconsistent units from
+// different speedtest servers: values above speedThreshold are assumed to
+// already be Bytes/sec, anything lower is assumed to be Mbps.
+func toBps(rawValue float64) float64 {
+	if rawValue > speedThreshold {
+		log.Warnf("Anomalously high speed value detected (%.2f). Assuming unit is Bytes/sec.", rawValue)
+		return rawValue
+	}
+	return rawValue * 125000
+}
@@ -2,20 +2,40 @@ package exporter
 
 import (
 	"fmt"
-	"strings" // Added for the URL fix
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/showwin/speedtest-go/speedtest"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/C0mmanderCrud/speedtest_exporter/internal/backend"
 )
 
 const (
 	namespace = "speedtest"
-	// Sanity threshold for speed values. If a value is > 20,000,
-	// we assume it's an anomaly reported in B/s, not Mbps.
-	speedThreshold = 20000.0
+	// defaultPingSamples is how many pings are sent per scrape when the
+	// exporter is not configured with an explicit sample count.
+	defaultPingSamples = 10
+	// defaultScrapeInterval is how often ModeScheduled runs a speedtest
+	// when the exporter is not configured with an explicit interval.
+	defaultScrapeInterval = 30 * time.Minute
+	// resultRingSize bounds how many RunTest results ResultByUUID can look
+	// up; older results are evicted once it's full.
+	resultRingSize = 100
+)
+
+// Mode controls when Exporter actually runs a speedtest.
+type Mode int
+
+const (
+	// ModeOnDemand runs a full speedtest synchronously on every Collect,
+	// serialising concurrent scrapes onto a single in-flight test run.
+	ModeOnDemand Mode = iota
+	// ModeScheduled runs speedtests on a fixed interval in the background
+	// and serves the most recently cached result on every Collect.
+	ModeScheduled
 )
 
 var (
@@ -31,7 +51,31 @@ var (
 	)
 	latency = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "", "latency_seconds"),
-		"Measured latency on last speed test",
+		"Median measured latency on last speed test",
+		[]string{"test_uuid", "user_lat", "user_lon", "user_ip", "user_isp", "server_lat", "server_lon", "server_id", "server_name", "server_country", "distance"},
+		nil,
+	)
+	latencyMin = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "latency_min_seconds"),
+		"Minimum measured latency across ping samples on last speed test",
+		[]string{"test_uuid", "user_lat", "user_lon", "user_ip", "user_isp", "server_lat", "server_lon", "server_id", "server_name", "server_country", "distance"},
+		nil,
+	)
+	latencyMax = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "latency_max_seconds"),
+		"Maximum measured latency across ping samples on last speed test",
+		[]string{"test_uuid", "user_lat", "user_lon", "user_ip", "user_isp", "server_lat", "server_lon", "server_id", "server_name", "server_country", "distance"},
+		nil,
+	)
+	jitter = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "jitter_seconds"),
+		"Mean absolute difference between consecutive ping samples on last speed test",
+		[]string{"test_uuid", "user_lat", "user_lon", "user_ip", "user_isp", "server_lat", "server_lon", "server_id", "server_name", "server_country", "distance"},
+		nil,
+	)
+	packetLoss = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "packet_loss_ratio"),
+		"Fraction of ping samples that timed out on last speed test",
 		[]string{"test_uuid", "user_lat", "user_lon", "user_ip", "user_isp", "server_lat", "server_lon", "server_id", "server_name", "server_country", "distance"},
 		nil,
 	)
@@ -47,21 +91,73 @@ var (
 		[]string{"test_uuid", "user_lat", "user_lon", "user_ip", "user_isp", "server_lat", "server_lon", "server_id", "server_name", "server_country", "distance"},
 		nil,
 	)
+	ageSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "age_seconds"),
+		"Age in seconds of the result being served, always 0 in on-demand mode.",
+		[]string{"test_uuid"}, nil,
+	)
+	selectedServerInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "selected_server_info"),
+		"Information about the server chosen for the last speed test. Value is always 1.",
+		[]string{"test_uuid", "server_id", "server_name", "server_country", "distance_km", "selection_strategy"},
+		nil,
+	)
 )
 
-// Exporter runs speedtest and exports them using
-// the prometheus metrics package.
+// Exporter runs speedtests through a backend.Backend and exports them using
+// the prometheus metrics package. The concrete backend (speedtest-go,
+// ookla-cli, iperf3, ...) is injected by the caller; Exporter itself only
+// knows the backend.Backend interface.
 type Exporter struct {
-	serverID       int
-	serverFallback bool
+	backend        backend.Backend
+	serverIDs      []int
+	countryFilter  string
+	selection      backend.Selection
+	pingSamples    int
+	mode           Mode
+	scrapeInterval time.Duration
+
+	sf singleflight.Group
+
+	cacheMu       sync.RWMutex
+	cachedMetrics []prometheus.Metric
+	cachedUUID    string
+	cachedAt      time.Time
+
+	resultsMu   sync.Mutex
+	results     []*Result
+	resultsByID map[string]*Result
 }
 
-// New returns an initialized Exporter.
-func New(serverID int, serverFallback bool) (*Exporter, error) {
-	return &Exporter{
-		serverID:       serverID,
-		serverFallback: serverFallback,
-	}, nil
+// New returns an initialized Exporter backed by be. serverIDs and
+// countryFilter narrow the pool of candidate servers be.SelectServer
+// considers on each run; pass serverIDs as nil (or countryFilter as "") to
+// consider its whole server list. In ModeScheduled, it immediately starts a
+// background goroutine that runs a speedtest every scrapeInterval;
+// scrapeInterval is ignored in ModeOnDemand.
+func New(be backend.Backend, serverIDs []int, countryFilter string, selection backend.Selection, pingSamples int, mode Mode, scrapeInterval time.Duration) (*Exporter, error) {
+	if pingSamples <= 0 {
+		pingSamples = defaultPingSamples
+	}
+	if scrapeInterval <= 0 {
+		scrapeInterval = defaultScrapeInterval
+	}
+
+	e := &Exporter{
+		backend:        be,
+		serverIDs:      serverIDs,
+		countryFilter:  countryFilter,
+		selection:      selection,
+		pingSamples:    pingSamples,
+		mode:           mode,
+		scrapeInterval: scrapeInterval,
+	}
+
+	if e.mode == ModeScheduled {
+		go e.scheduleLoop()
+	}
+
+	return e, nil
 }
 
 // Describe describes all the metrics. It implements prometheus.Collector.
@@ -69,160 +165,332 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- up
 	ch <- scrapeDurationSeconds
 	ch <- latency
+	ch <- latencyMin
+	ch <- latencyMax
+	ch <- jitter
+	ch <- packetLoss
 	ch <- upload
 	ch <- download
+	ch <- ageSeconds
+	ch <- selectedServerInfo
 }
 
 // Collect fetches the stats and delivers them as Prometheus metrics.
 // It implements prometheus.Collector.
 func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	if e.mode == ModeScheduled {
+		e.collectCached(ch)
+		return
+	}
+	e.collectOnDemand(ch)
+}
+
+// onceResult is the shared result of a single speedtest run, cached in the
+// singleflight group so overlapping on-demand scrapes see the same test.
+type onceResult struct {
+	metrics  []prometheus.Metric
+	testUUID string
+}
+
+// collectOnDemand runs a speedtest synchronously for this scrape, sharing a
+// single in-flight run across any scrapes that overlap it.
+func (e *Exporter) collectOnDemand(ch chan<- prometheus.Metric) {
+	v, _, _ := e.sf.Do("speedtest", func() (interface{}, error) {
+		metrics, testUUID := e.runOnce()
+		return onceResult{metrics: metrics, testUUID: testUUID}, nil
+	})
+
+	result := v.(onceResult)
+	for _, m := range result.metrics {
+		ch <- m
+	}
+	ch <- prometheus.MustNewConstMetric(ageSeconds, prometheus.GaugeValue, 0, result.testUUID)
+}
+
+// collectCached re-emits the most recent result produced by scheduleLoop,
+// along with its age, without running a new speedtest.
+func (e *Exporter) collectCached(ch chan<- prometheus.Metric) {
+	e.cacheMu.RLock()
+	metrics := e.cachedMetrics
+	testUUID := e.cachedUUID
+	cachedAt := e.cachedAt
+	e.cacheMu.RUnlock()
+
+	if metrics == nil {
+		log.Warn("no cached speedtest result yet, background scrape has not completed")
+		ch <- prometheus.MustNewConstMetric(up, prometheus.GaugeValue, 0.0, "")
+		return
+	}
+
+	for _, m := range metrics {
+		ch <- m
+	}
+	ch <- prometheus.MustNewConstMetric(ageSeconds, prometheus.GaugeValue, time.Since(cachedAt).Seconds(), testUUID)
+}
+
+// scheduleLoop runs a speedtest immediately and then every scrapeInterval,
+// caching each result for collectCached to serve. It runs for the lifetime
+// of the process.
+func (e *Exporter) scheduleLoop() {
+	e.runAndCache()
+
+	ticker := time.NewTicker(e.scrapeInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		e.runAndCache()
+	}
+}
+
+func (e *Exporter) runAndCache() {
+	metrics, testUUID := e.runOnce()
+
+	e.cacheMu.Lock()
+	e.cachedMetrics = metrics
+	e.cachedUUID = testUUID
+	e.cachedAt = time.Now()
+	e.cacheMu.Unlock()
+}
+
+// runOnce performs one full speedtest and returns every metric it produced,
+// including scrape_duration_seconds and up, labeled with a freshly minted
+// test_uuid.
+func (e *Exporter) runOnce() ([]prometheus.Metric, string) {
 	testUUID := uuid.New().String()
 	start := time.Now()
-	ok := e.speedtest(testUUID, ch)
 
-	// Always report up and scrape_duration, regardless of test success
+	metricsCh := make(chan prometheus.Metric, 32)
+	ok := e.speedtest(testUUID, metricsCh)
+	close(metricsCh)
+
+	metrics := make([]prometheus.Metric, 0, 32)
+	for m := range metricsCh {
+		metrics = append(metrics, m)
+	}
+
 	duration := time.Since(start).Seconds()
-	ch <- prometheus.MustNewConstMetric(scrapeDurationSeconds, prometheus.GaugeValue, duration, testUUID)
+	metrics = append(metrics, prometheus.MustNewConstMetric(scrapeDurationSeconds, prometheus.GaugeValue, duration, testUUID))
 
 	if ok {
-		ch <- prometheus.MustNewConstMetric(up, prometheus.GaugeValue, 1.0, testUUID)
+		metrics = append(metrics, prometheus.MustNewConstMetric(up, prometheus.GaugeValue, 1.0, testUUID))
 	} else {
-		ch <- prometheus.MustNewConstMetric(up, prometheus.GaugeValue, 0.0, testUUID)
+		metrics = append(metrics, prometheus.MustNewConstMetric(up, prometheus.GaugeValue, 0.0, testUUID))
 	}
+
+	return metrics, testUUID
 }
 
 func (e *Exporter) speedtest(testUUID string, ch chan<- prometheus.Metric) bool {
-	user, err := speedtest.FetchUserInfo()
+	user, err := e.backend.FetchUser()
 	if err != nil {
 		log.Errorf("could not fetch user information: %s", err.Error())
 		return false
 	}
 
-	serverList, err := speedtest.FetchServerList(user)
+	server, distanceKm, err := e.backend.SelectServer(user, e.serverIDs, e.countryFilter, e.selection, false, false)
 	if err != nil {
-		log.Errorf("could not fetch server list: %s", err.Error())
+		log.Errorf("failed to select a server: %s", err.Error())
 		return false
 	}
 
-	var server *speedtest.Server
+	log.Infof("Starting speedtest with server %s (%s, %s) [id: %s, distance: %.1fkm]", server.Name, server.Country, server.Host, server.ID, distanceKm)
 
-	if e.serverID == -1 {
-		if len(serverList.Servers) == 0 {
-			log.Error("server list is empty, cannot select the closest server")
-			return false
-		}
-		server = serverList.Servers[0]
-	} else {
-		servers, err := serverList.FindServer([]int{e.serverID})
-		if err != nil {
-			log.Errorf("failed to find server with ID %d: %v", e.serverID, err)
-			return false
-		}
-
-		if len(servers) == 0 {
-			log.Errorf("could not find your chosen server ID %d in the list of available servers", e.serverID)
-			if !e.serverFallback {
-				log.Info("server_fallback is not enabled, failing this test")
-				return false
-			}
-			log.Info("server_fallback is enabled, falling back to the closest server")
-			if len(serverList.Servers) == 0 {
-				log.Error("server list is empty, cannot fall back to the closest server")
-				return false
-			}
-			server = serverList.Servers[0]
-		} else {
-			server = servers[0]
-		}
-	}
-
-	// WORKAROUND: Detect and correct malformed URLs (e.g., "http//...")
-	// that can be produced by the speedtest-go library or server lists.
-	if strings.HasPrefix(server.URL, "http//") {
-		correctedURL := strings.Replace(server.URL, "http//", "http://", 1)
-		log.Warnf("Malformed server URL detected, correcting from '%s' to '%s'", server.URL, correctedURL)
-		server.URL = correctedURL
-	}
-
-	log.Infof("Starting speedtest with server %s (%s, %s) [id: %s]", server.Name, server.Country, server.Host, server.ID)
+	ch <- prometheus.MustNewConstMetric(
+		selectedServerInfo, prometheus.GaugeValue, 1.0,
+		testUUID, server.ID, server.Name, server.Country, fmt.Sprintf("%f", distanceKm), e.selection.String(),
+	)
 
 	// Run all tests and report individual success/failure.
-	pingSuccess := pingTest(testUUID, user, server, ch)
-	downloadSuccess := downloadTest(testUUID, user, server, ch)
-	uploadSuccess := uploadTest(testUUID, user, server, ch)
+	pingSuccess := pingTest(testUUID, user, server, distanceKm, e.pingSamples, e.backend, ch)
+	downloadSuccess := downloadTest(testUUID, user, server, distanceKm, e.backend, ch)
+	uploadSuccess := uploadTest(testUUID, user, server, distanceKm, e.backend, ch)
 
 	// The overall test is successful if all parts succeed.
 	return pingSuccess && downloadSuccess && uploadSuccess
 }
 
-func pingTest(testUUID string, user *speedtest.User, server *speedtest.Server, ch chan<- prometheus.Metric) bool {
-	err := server.PingTest()
+// pingTest asks be to sample latency against server, reporting min/median/max
+// latency, jitter (the mean absolute difference between consecutive samples)
+// and the fraction of samples that timed out.
+func pingTest(testUUID string, user *backend.UserInfo, server *backend.ServerInfo, distanceKm float64, samples int, be backend.Backend, ch chan<- prometheus.Metric) bool {
+	stats, err := be.Ping(server, samples)
 	if err != nil {
 		log.Errorf("failed to carry out ping test: %s", err.Error())
 		return false
 	}
 
-	ch <- prometheus.MustNewConstMetric(
-		latency, prometheus.GaugeValue, server.Latency.Seconds(),
-		testUUID, user.Lat, user.Lon, user.IP, user.Isp,
-		server.Lat, server.Lon, server.ID, server.Name, server.Country, fmt.Sprintf("%f", server.Distance),
-	)
-	log.Infof("Ping test successful. Latency: %s", server.Latency)
+	labels := []string{
+		testUUID, user.Lat, user.Lon, user.IP, user.ISP,
+		server.Lat, server.Lon, server.ID, server.Name, server.Country, fmt.Sprintf("%f", distanceKm),
+	}
+
+	ch <- prometheus.MustNewConstMetric(latency, prometheus.GaugeValue, stats.MedianSeconds, labels...)
+	ch <- prometheus.MustNewConstMetric(latencyMin, prometheus.GaugeValue, stats.MinSeconds, labels...)
+	ch <- prometheus.MustNewConstMetric(latencyMax, prometheus.GaugeValue, stats.MaxSeconds, labels...)
+	ch <- prometheus.MustNewConstMetric(jitter, prometheus.GaugeValue, stats.JitterSeconds, labels...)
+	ch <- prometheus.MustNewConstMetric(packetLoss, prometheus.GaugeValue, stats.PacketLossRatio, labels...)
+
+	log.Infof("Ping test successful. Median latency: %.3fs, jitter: %.3fs, packet loss: %.0f%% (%d samples)",
+		stats.MedianSeconds, stats.JitterSeconds, stats.PacketLossRatio*100, samples)
 	return true
 }
 
-func downloadTest(testUUID string, user *speedtest.User, server *speedtest.Server, ch chan<- prometheus.Metric) bool {
-	err := server.DownloadTest(false)
+func downloadTest(testUUID string, user *backend.UserInfo, server *backend.ServerInfo, distanceKm float64, be backend.Backend, ch chan<- prometheus.Metric) bool {
+	speedBps, err := be.Download(server)
 	if err != nil {
 		log.Errorf("failed to carry out download test: %s", err.Error())
 		return false
 	}
 
-	rawValue := server.DLSpeed
-	var speedBps float64
-
-	// Heuristic to handle inconsistent units from different speedtest servers.
-	if rawValue > speedThreshold {
-		log.Warnf("Anomalously high download speed value detected (%.2f). Assuming unit is Bytes/sec.", rawValue)
-		speedBps = rawValue // Assume value is already in Bytes/sec
-	} else {
-		// Assume value is in Mbps, convert to Bytes/sec (1 Mbps = 125,000 B/s)
-		speedBps = rawValue * 125000
-	}
-
 	ch <- prometheus.MustNewConstMetric(
 		download, prometheus.GaugeValue, speedBps,
-		testUUID, user.Lat, user.Lon, user.IP, user.Isp,
-		server.Lat, server.Lon, server.ID, server.Name, server.Country, fmt.Sprintf("%f", server.Distance),
+		testUUID, user.Lat, user.Lon, user.IP, user.ISP,
+		server.Lat, server.Lon, server.ID, server.Name, server.Country, fmt.Sprintf("%f", distanceKm),
 	)
 	log.Infof("Download test successful. Speed: %.2f B/s (%.2f MB/s)", speedBps, speedBps/1000/1000)
 	return true
 }
 
-func uploadTest(testUUID string, user *speedtest.User, server *speedtest.Server, ch chan<- prometheus.Metric) bool {
-	err := server.UploadTest(false)
+func uploadTest(testUUID string, user *backend.UserInfo, server *backend.ServerInfo, distanceKm float64, be backend.Backend, ch chan<- prometheus.Metric) bool {
+	speedBps, err := be.Upload(server)
 	if err != nil {
 		log.Errorf("failed to carry out upload test: %s", err.Error())
 		return false
 	}
 
-	rawValue := server.ULSpeed
-	var speedBps float64
-
-	// Heuristic to handle inconsistent units from different speedtest servers.
-	if rawValue > speedThreshold {
-		log.Warnf("Anomalously high upload speed value detected (%.2f). Assuming unit is Bytes/sec.", rawValue)
-		speedBps = rawValue // Assume value is already in Bytes/sec
-	} else {
-		// Assume value is in Mbps, convert to Bytes/sec (1 Mbps = 125,000 B/s)
-		speedBps = rawValue * 125000
-	}
-
 	ch <- prometheus.MustNewConstMetric(
 		upload, prometheus.GaugeValue, speedBps,
-		testUUID, user.Lat, user.Lon, user.IP, user.Isp,
-		server.Lat, server.Lon, server.ID, server.Name, server.Country, fmt.Sprintf("%f", server.Distance),
+		testUUID, user.Lat, user.Lon, user.IP, user.ISP,
+		server.Lat, server.Lon, server.ID, server.Name, server.Country, fmt.Sprintf("%f", distanceKm),
 	)
 	log.Infof("Upload test successful. Speed: %.2f B/s (%.2f MB/s)", speedBps, speedBps/1000/1000)
 	return true
 }
+
+// RunOptions overrides Exporter's configured defaults for a single ad-hoc
+// RunTest call, e.g. one triggered over HTTP.
+type RunOptions struct {
+	// ServerIDs, if non-empty, overrides the Exporter's configured
+	// serverIDs for this run only.
+	ServerIDs []int
+	// SkipDownload and SkipUpload allow a latency-only probe.
+	SkipDownload bool
+	SkipUpload   bool
+}
+
+// Result is the full, structured outcome of a single speedtest run, as
+// returned by RunTest and looked up via ResultByUUID.
+type Result struct {
+	TestUUID          string              `json:"test_uuid"`
+	Timestamp         time.Time           `json:"timestamp"`
+	DurationSeconds   float64             `json:"duration_seconds"`
+	Success           bool                `json:"success"`
+	User              *backend.UserInfo   `json:"user,omitempty"`
+	Server            *backend.ServerInfo `json:"server,omitempty"`
+	DistanceKm        float64             `json:"distance_km"`
+	LatencySeconds    float64             `json:"latency_seconds"`
+	LatencyMinSeconds float64             `json:"latency_min_seconds"`
+	LatencyMaxSeconds float64             `json:"latency_max_seconds"`
+	JitterSeconds     float64             `json:"jitter_seconds"`
+	PacketLossRatio   float64             `json:"packet_loss_ratio"`
+	DownloadBps       *float64            `json:"download_bps,omitempty"`
+	UploadBps         *float64            `json:"upload_bps,omitempty"`
+	Error             string              `json:"error,omitempty"`
+}
+
+// RunTest performs a single, synchronous speedtest, honoring opts, and
+// records the result so a later ResultByUUID call can retrieve it. Unlike
+// Collect, it never consults or populates the ModeScheduled cache and is
+// not deduplicated against concurrent scrapes.
+func (e *Exporter) RunTest(opts RunOptions) *Result {
+	testUUID := uuid.New().String()
+	start := time.Now()
+	result := &Result{TestUUID: testUUID, Timestamp: start}
+
+	defer func() {
+		result.DurationSeconds = time.Since(start).Seconds()
+		e.recordResult(result)
+	}()
+
+	serverIDs := e.serverIDs
+	if len(opts.ServerIDs) > 0 {
+		serverIDs = opts.ServerIDs
+	}
+
+	user, err := e.backend.FetchUser()
+	if err != nil {
+		result.Error = fmt.Sprintf("could not fetch user information: %s", err.Error())
+		return result
+	}
+	result.User = user
+
+	server, distanceKm, err := e.backend.SelectServer(user, serverIDs, e.countryFilter, e.selection, opts.SkipDownload, opts.SkipUpload)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to select a server: %s", err.Error())
+		return result
+	}
+	result.Server = server
+	result.DistanceKm = distanceKm
+
+	stats, err := e.backend.Ping(server, e.pingSamples)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to carry out ping test: %s", err.Error())
+		return result
+	}
+	result.LatencySeconds = stats.MedianSeconds
+	result.LatencyMinSeconds = stats.MinSeconds
+	result.LatencyMaxSeconds = stats.MaxSeconds
+	result.JitterSeconds = stats.JitterSeconds
+	result.PacketLossRatio = stats.PacketLossRatio
+
+	success := true
+	if !opts.SkipDownload {
+		bps, err := e.backend.Download(server)
+		if err != nil {
+			log.Errorf("failed to carry out download test: %s", err.Error())
+			success = false
+		} else {
+			result.DownloadBps = &bps
+		}
+	}
+	if !opts.SkipUpload {
+		bps, err := e.backend.Upload(server)
+		if err != nil {
+			log.Errorf("failed to carry out upload test: %s", err.Error())
+			success = false
+		} else {
+			result.UploadBps = &bps
+		}
+	}
+
+	result.Success = success
+	return result
+}
+
+// recordResult adds r to the result ring buffer, evicting the oldest entry
+// once resultRingSize is exceeded.
+func (e *Exporter) recordResult(r *Result) {
+	e.resultsMu.Lock()
+	defer e.resultsMu.Unlock()
+
+	if e.resultsByID == nil {
+		e.resultsByID = make(map[string]*Result)
+	}
+	e.results = append(e.results, r)
+	e.resultsByID[r.TestUUID] = r
+
+	if len(e.results) > resultRingSize {
+		oldest := e.results[0]
+		e.results = e.results[1:]
+		delete(e.resultsByID, oldest.TestUUID)
+	}
+}
+
+// ResultByUUID looks up a previously recorded RunTest result by its
+// test_uuid. ok is false if testUUID is unknown or has been evicted from
+// the ring buffer.
+func (e *Exporter) ResultByUUID(testUUID string) (*Result, bool) {
+	e.resultsMu.Lock()
+	defer e.resultsMu.Unlock()
+	r, ok := e.resultsByID[testUUID]
+	return r, ok
+}
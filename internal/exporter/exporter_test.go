@@ -0,0 +1,182 @@
+package exporter
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/C0mmanderCrud/speedtest_exporter/internal/backend"
+)
+
+// fakeBackend is a backend.Backend double that never touches the network.
+// selectServerHook, when set, runs synchronously inside SelectServer so
+// tests can observe or delay in-flight calls.
+type fakeBackend struct {
+	selectServerCalls int32
+	selectServerHook  func(skipDownload, skipUpload bool)
+
+	downloadCalls int32
+	uploadCalls   int32
+}
+
+func (b *fakeBackend) Name() string { return "fake" }
+
+func (b *fakeBackend) FetchUser() (*backend.UserInfo, error) {
+	return &backend.UserInfo{IP: "203.0.113.1", ISP: "Test ISP"}, nil
+}
+
+func (b *fakeBackend) SelectServer(user *backend.UserInfo, serverIDs []int, countryFilter string, selection backend.Selection, skipDownload, skipUpload bool) (*backend.ServerInfo, float64, error) {
+	atomic.AddInt32(&b.selectServerCalls, 1)
+	if b.selectServerHook != nil {
+		b.selectServerHook(skipDownload, skipUpload)
+	}
+	return &backend.ServerInfo{ID: "1", Name: "Test Server"}, 12.5, nil
+}
+
+func (b *fakeBackend) Ping(server *backend.ServerInfo, samples int) (backend.PingStats, error) {
+	return backend.PingStats{MedianSeconds: 0.01, MinSeconds: 0.005, MaxSeconds: 0.02}, nil
+}
+
+func (b *fakeBackend) Download(server *backend.ServerInfo) (float64, error) {
+	atomic.AddInt32(&b.downloadCalls, 1)
+	return 1000, nil
+}
+
+func (b *fakeBackend) Upload(server *backend.ServerInfo) (float64, error) {
+	atomic.AddInt32(&b.uploadCalls, 1)
+	return 500, nil
+}
+
+// collect drains a Collect call into a slice, for assertions on labels.
+func collect(e *Exporter) []prometheus.Metric {
+	ch := make(chan prometheus.Metric, 32)
+	e.Collect(ch)
+	close(ch)
+
+	var metrics []prometheus.Metric
+	for m := range ch {
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+func TestModeOnDemandDedupesConcurrentScrapes(t *testing.T) {
+	release := make(chan struct{})
+
+	be := &fakeBackend{
+		selectServerHook: func(skipDownload, skipUpload bool) {
+			<-release
+		},
+	}
+
+	e, err := New(be, nil, "", backend.SelectionNearest, 3, ModeOnDemand, 0)
+	if err != nil {
+		t.Fatalf("New returned an unexpected error: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			defer wg.Done()
+			collect(e)
+		}()
+	}
+
+	// Give both goroutines a chance to reach the singleflight call before
+	// releasing it, so we actually exercise deduplication rather than two
+	// back-to-back independent runs.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&be.selectServerCalls); got != 1 {
+		t.Errorf("SelectServer was called %d times for two overlapping on-demand scrapes, want 1", got)
+	}
+}
+
+func TestModeScheduledServesCachedResultWithoutRescraping(t *testing.T) {
+	be := &fakeBackend{}
+
+	e, err := New(be, nil, "", backend.SelectionNearest, 3, ModeScheduled, time.Hour)
+	if err != nil {
+		t.Fatalf("New returned an unexpected error: %s", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&be.selectServerCalls) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the scheduled background scrape to run")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	collect(e)
+	collect(e)
+
+	if got := atomic.LoadInt32(&be.selectServerCalls); got != 1 {
+		t.Errorf("SelectServer was called %d times across repeated cached scrapes, want 1", got)
+	}
+}
+
+func TestRunTestSkipFlags(t *testing.T) {
+	var gotSkipDownload, gotSkipUpload bool
+	be := &fakeBackend{
+		selectServerHook: func(skipDownload, skipUpload bool) {
+			gotSkipDownload = skipDownload
+			gotSkipUpload = skipUpload
+		},
+	}
+
+	e, err := New(be, nil, "", backend.SelectionNearest, 3, ModeOnDemand, 0)
+	if err != nil {
+		t.Fatalf("New returned an unexpected error: %s", err)
+	}
+
+	result := e.RunTest(RunOptions{SkipDownload: true})
+
+	if !gotSkipDownload || gotSkipUpload {
+		t.Errorf("SelectServer saw skipDownload=%v, skipUpload=%v, want true, false", gotSkipDownload, gotSkipUpload)
+	}
+	if atomic.LoadInt32(&be.downloadCalls) != 0 {
+		t.Error("Download was called despite RunOptions.SkipDownload")
+	}
+	if atomic.LoadInt32(&be.uploadCalls) != 1 {
+		t.Error("Upload was not called despite RunOptions.SkipUpload being unset")
+	}
+	if result.DownloadBps != nil {
+		t.Errorf("DownloadBps = %v, want nil for a skipped download", *result.DownloadBps)
+	}
+	if result.UploadBps == nil {
+		t.Error("UploadBps is nil, want a measured value")
+	}
+	if !result.Success {
+		t.Errorf("Success = false, want true (error: %s)", result.Error)
+	}
+}
+
+func TestRecordResultRingBufferEviction(t *testing.T) {
+	e, err := New(&fakeBackend{}, nil, "", backend.SelectionNearest, 3, ModeOnDemand, 0)
+	if err != nil {
+		t.Fatalf("New returned an unexpected error: %s", err)
+	}
+
+	var uuids []string
+	for i := 0; i < resultRingSize+5; i++ {
+		uuids = append(uuids, e.RunTest(RunOptions{}).TestUUID)
+	}
+
+	for i, id := range uuids[:5] {
+		if _, ok := e.ResultByUUID(id); ok {
+			t.Errorf("uuids[%d] (%s) should have been evicted from the ring buffer, but was found", i, id)
+		}
+	}
+	for i, id := range uuids[5:] {
+		if _, ok := e.ResultByUUID(id); !ok {
+			t.Errorf("uuids[%d] (%s) should still be in the ring buffer, but was not found", i+5, id)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package httpapi exposes an HTTP trigger for ad-hoc speedtest runs,
+// meant to be mounted on the same server as the Prometheus /metrics
+// handler: POST /runtest forces a synchronous run and returns its full
+// result as JSON, and GET /result/{uuid} fetches a prior run's result from
+// an in-memory ring buffer.
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/C0mmanderCrud/speedtest_exporter/internal/exporter"
+)
+
+// resultProvider is the subset of *exporter.Exporter this package depends
+// on, so tests can fake it without spinning up a real backend.
+type resultProvider interface {
+	RunTest(opts exporter.RunOptions) *exporter.Result
+	ResultByUUID(testUUID string) (*exporter.Result, bool)
+}
+
+// Handler mounts /runtest and /result/ next to the exporter's /metrics
+// handler.
+type Handler struct {
+	exp resultProvider
+}
+
+// New returns a Handler backed by exp.
+func New(exp resultProvider) *Handler {
+	return &Handler{exp: exp}
+}
+
+// RegisterRoutes mounts the handler's routes on mux.
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/runtest", h.handleRunTest)
+	mux.HandleFunc("/result/", h.handleResult)
+}
+
+// handleRunTest implements POST /runtest. Query parameters:
+//
+//	server_id - overrides the exporter's configured server(s) with a
+//	            single server ID for this run only
+//	download  - set to "false" to skip the download phase
+//	upload    - set to "false" to skip the upload phase
+func (h *Handler) handleRunTest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed, expected POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var opts exporter.RunOptions
+
+	q := r.URL.Query()
+	if idStr := q.Get("server_id"); idStr != "" {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			http.Error(w, "invalid server_id: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		opts.ServerIDs = []int{id}
+	}
+	opts.SkipDownload = q.Get("download") == "false"
+	opts.SkipUpload = q.Get("upload") == "false"
+
+	result := h.exp.RunTest(opts)
+	writeJSON(w, result, result.Success)
+}
+
+// handleResult implements GET /result/{uuid}.
+func (h *Handler) handleResult(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed, expected GET", http.StatusMethodNotAllowed)
+		return
+	}
+
+	testUUID := strings.TrimPrefix(r.URL.Path, "/result/")
+	if testUUID == "" {
+		http.Error(w, "missing test uuid in path", http.StatusBadRequest)
+		return
+	}
+
+	result, ok := h.exp.ResultByUUID(testUUID)
+	if !ok {
+		http.Error(w, "unknown or expired test uuid", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, result, true)
+}
+
+func writeJSON(w http.ResponseWriter, result *exporter.Result, success bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if !success {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Errorf("failed to encode speedtest result as JSON: %s", err.Error())
+	}
+}
@@ -0,0 +1,175 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/C0mmanderCrud/speedtest_exporter/internal/exporter"
+)
+
+// fakeExp is a resultProvider double so tests never touch a real backend.
+type fakeExp struct {
+	runTestOpts   exporter.RunOptions
+	runTestResult *exporter.Result
+
+	resultByUUID map[string]*exporter.Result
+}
+
+func (f *fakeExp) RunTest(opts exporter.RunOptions) *exporter.Result {
+	f.runTestOpts = opts
+	if f.runTestResult != nil {
+		return f.runTestResult
+	}
+	return &exporter.Result{TestUUID: "test-uuid", Success: true}
+}
+
+func (f *fakeExp) ResultByUUID(testUUID string) (*exporter.Result, bool) {
+	r, ok := f.resultByUUID[testUUID]
+	return r, ok
+}
+
+func TestHandleRunTestWrongMethod(t *testing.T) {
+	h := New(&fakeExp{})
+	req := httptest.NewRequest(http.MethodGet, "/runtest", nil)
+	rec := httptest.NewRecorder()
+
+	h.handleRunTest(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandleRunTestInvalidServerID(t *testing.T) {
+	h := New(&fakeExp{})
+	req := httptest.NewRequest(http.MethodPost, "/runtest?server_id=not-a-number", nil)
+	rec := httptest.NewRecorder()
+
+	h.handleRunTest(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleRunTestSkipFlagMapping(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		wantSkipDownload bool
+		wantSkipUpload   bool
+		wantServerIDs    []int
+	}{
+		{name: "no params", query: "", wantSkipDownload: false, wantSkipUpload: false},
+		{name: "download=false skips download only", query: "download=false", wantSkipDownload: true, wantSkipUpload: false},
+		{name: "upload=false skips upload only", query: "upload=false", wantSkipDownload: false, wantSkipUpload: true},
+		{name: "download=true does not skip", query: "download=true", wantSkipDownload: false, wantSkipUpload: false},
+		{name: "server_id overrides ServerIDs", query: "server_id=42", wantServerIDs: []int{42}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fe := &fakeExp{}
+			h := New(fe)
+			req := httptest.NewRequest(http.MethodPost, "/runtest?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			h.handleRunTest(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if fe.runTestOpts.SkipDownload != tt.wantSkipDownload {
+				t.Errorf("SkipDownload = %v, want %v", fe.runTestOpts.SkipDownload, tt.wantSkipDownload)
+			}
+			if fe.runTestOpts.SkipUpload != tt.wantSkipUpload {
+				t.Errorf("SkipUpload = %v, want %v", fe.runTestOpts.SkipUpload, tt.wantSkipUpload)
+			}
+			if tt.wantServerIDs != nil {
+				if len(fe.runTestOpts.ServerIDs) != len(tt.wantServerIDs) || fe.runTestOpts.ServerIDs[0] != tt.wantServerIDs[0] {
+					t.Errorf("ServerIDs = %v, want %v", fe.runTestOpts.ServerIDs, tt.wantServerIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleRunTestWritesFailureStatus(t *testing.T) {
+	fe := &fakeExp{runTestResult: &exporter.Result{TestUUID: "test-uuid", Success: false, Error: "boom"}}
+	h := New(fe)
+	req := httptest.NewRequest(http.MethodPost, "/runtest", nil)
+	rec := httptest.NewRecorder()
+
+	h.handleRunTest(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d for a failed run", rec.Code, http.StatusInternalServerError)
+	}
+
+	var got exporter.Result
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("could not decode response body: %s", err)
+	}
+	if got.TestUUID != "test-uuid" {
+		t.Errorf("TestUUID = %q, want %q", got.TestUUID, "test-uuid")
+	}
+}
+
+func TestHandleResultWrongMethod(t *testing.T) {
+	h := New(&fakeExp{})
+	req := httptest.NewRequest(http.MethodPost, "/result/test-uuid", nil)
+	rec := httptest.NewRecorder()
+
+	h.handleResult(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandleResultMissingUUID(t *testing.T) {
+	h := New(&fakeExp{})
+	req := httptest.NewRequest(http.MethodGet, "/result/", nil)
+	rec := httptest.NewRecorder()
+
+	h.handleResult(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleResultUnknownUUID(t *testing.T) {
+	h := New(&fakeExp{resultByUUID: map[string]*exporter.Result{}})
+	req := httptest.NewRequest(http.MethodGet, "/result/does-not-exist", nil)
+	rec := httptest.NewRecorder()
+
+	h.handleResult(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleResultKnownUUID(t *testing.T) {
+	want := &exporter.Result{TestUUID: "known-uuid", Success: true}
+	h := New(&fakeExp{resultByUUID: map[string]*exporter.Result{"known-uuid": want}})
+	req := httptest.NewRequest(http.MethodGet, "/result/known-uuid", nil)
+	rec := httptest.NewRecorder()
+
+	h.handleResult(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var got exporter.Result
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("could not decode response body: %s", err)
+	}
+	if got.TestUUID != want.TestUUID {
+		t.Errorf("TestUUID = %q, want %q", got.TestUUID, want.TestUUID)
+	}
+}